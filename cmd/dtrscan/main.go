@@ -0,0 +1,242 @@
+// Command dtrscan walks a set of DTR namespaces and starts vulnerability
+// scans for tags that are stale or have never been scanned.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"dtrscan/internal/config"
+	"dtrscan/internal/daemon"
+	"dtrscan/internal/dtr"
+	"dtrscan/internal/metrics"
+	"dtrscan/internal/report"
+	"dtrscan/internal/scanner"
+	"dtrscan/internal/statecache"
+)
+
+const (
+	defaultDays = 10000
+	usage       = `
+Usage: dtrscan --user [DTR user] --token [DTR access token] [OPTION]
+
+Options:
+  -h --help          Show this help
+  --file             Namespaces file (defaults to namespaces.yaml)
+  --url              DTR URL (defaults to dtr.company.com)
+  --days             Force scan if scan is older than 'days'
+  --no_dry_run       Start scans
+  --workers          Number of concurrent workers (defaults to 10)
+  --retries          Number of retries per HTTP call on 5xx/timeout (defaults to 3)
+  --retry-backoff    Initial sleep between retries, doubles each attempt (defaults to 2s)
+  --retry-timeout    Give up retrying a call after this long (defaults to 60s)
+  --http-timeout     Per-request HTTP timeout (defaults to 90s)
+  --report-format    Report format: json, csv or html (defaults to json)
+  --report-out       Report output file (defaults to stdout)
+  --state-file       Scan-state cache file (defaults under $XDG_CACHE_HOME)
+  --refresh          Ignore the scan-state cache and re-check every tag
+  --state-max-age    Expire cache entries not decided within this long (defaults to 720h)
+  --dump-effective-config  Print the repos/tags each namespace rule selects, after registry-side filtering, and exit
+  --serve            Run as a daemon, scanning on --interval and serving Prometheus metrics on this address (e.g. :9090)
+  --interval         Time between scans in --serve mode (defaults to 6h)
+`
+)
+
+func main() {
+	var (
+		userId       = flag.String("user", "", "DTR User id")
+		token        = flag.String("token", "", "DTR Access Token")
+		url          = flag.String("url", "https://dtr.company.com", "DTR URL")
+		nameFile     = flag.String("file", "namespaces.yaml", "Namespaces file")
+		days         = flag.Int("days", defaultDays, "Force scan if older than days")
+		noDryRun     = flag.Bool("no_dry_run", false, "Start scans")
+		workers      = flag.Int("workers", 10, "Number of concurrent workers")
+		retries      = flag.Int("retries", 3, "Number of retries per HTTP call")
+		retryBackoff = flag.Duration("retry-backoff", 2*time.Second, "Initial sleep between retries")
+		retryTimeout = flag.Duration("retry-timeout", 60*time.Second, "Give up retrying a call after this long")
+		httpTimeout  = flag.Duration("http-timeout", 90*time.Second, "Per-request HTTP timeout")
+		reportFormat = flag.String("report-format", "json", "Report format: json, csv or html")
+		reportOut    = flag.String("report-out", "", "Report output file (defaults to stdout)")
+		stateFile    = flag.String("state-file", "", "Scan-state cache file (defaults under $XDG_CACHE_HOME)")
+		refresh      = flag.Bool("refresh", false, "Ignore the scan-state cache and re-check every tag")
+		stateMaxAge  = flag.Duration("state-max-age", 30*24*time.Hour, "Expire cache entries not decided within this long")
+		dumpConfig   = flag.Bool("dump-effective-config", false, "Print the repos/tags each namespace rule selects and exit")
+		serve        = flag.String("serve", "", "Run as a daemon, serving Prometheus metrics on this address (e.g. :9090)")
+		interval     = flag.Duration("interval", 6*time.Hour, "Time between scans in --serve mode")
+	)
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+
+	log.SetFlags(log.LstdFlags)
+
+	if *userId == "" || *token == "" {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		os.Exit(0)
+	}
+	log.Printf("user %v file %v url %v\n", *userId, *nameFile, *url)
+
+	ns, nserr := config.Load(*nameFile)
+	if nserr != nil {
+		log.Fatalf("Error getting namespaces %v\n", nserr)
+	}
+
+	if *days <= 0 || *days > defaultDays {
+		log.Printf("Invalid number of days entered, must be between 1 and %v. Setting days to %v", defaultDays, defaultDays)
+		*days = defaultDays
+	}
+
+	// If --no_dry_run is not set dry_run evaulate to True and no
+	// scans will be started. Otherwise, if --no_dry_run is present dry_run
+	// is false and scans will run
+	// Specify no_dry_run to start scans otherwise no tags will be scanned (a 'dry run')
+	dryRun := !*noDryRun
+
+	if *stateFile == "" {
+		*stateFile = statecache.DefaultPath()
+	}
+	var cache *statecache.Cache
+	if *refresh {
+		cache = statecache.New(*stateFile)
+	} else {
+		var err error
+		cache, err = statecache.Load(*stateFile)
+		if err != nil {
+			log.Fatalf("Error loading state file %v: %v\n", *stateFile, err)
+		}
+	}
+	cache.Prune(*stateMaxAge, time.Now().UTC())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigCh
+		log.Printf("Received %v, cancelling in-flight requests\n", sig)
+		cancel()
+		<-sigCh // a second signal forces immediate shutdown
+		os.Exit(1)
+	}()
+	defer cancel()
+
+	registry := dtr.NewClient(dtr.Config{
+		UserId:       *userId,
+		Token:        *token,
+		Url:          *url,
+		Retries:      *retries,
+		RetryBackoff: *retryBackoff,
+		RetryTimeout: *retryTimeout,
+		HTTPTimeout:  *httpTimeout,
+	})
+
+	if *dumpConfig {
+		effective, errs := scanner.Explain(ctx, registry, ns.Namespaces)
+		for _, err := range errs {
+			log.Printf("dump-effective-config error: %v", err)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(effective); err != nil {
+			log.Fatalf("Error printing effective config: %v\n", err)
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	runOnce := func(ctx context.Context) error {
+		return runScan(ctx, registry, cache, ns.Namespaces, scanOpts{
+			days: *days, dryRun: dryRun, workers: *workers,
+			reportFormat: *reportFormat, reportOut: *reportOut, stateFile: *stateFile,
+		})
+	}
+
+	if *serve != "" {
+		log.Printf("Serving metrics on %v, scanning every %v\n", *serve, *interval)
+		if err := daemon.Serve(ctx, daemon.Options{
+			Addr:     *serve,
+			Interval: *interval,
+			Registry: registry,
+			Scan:     runOnce,
+		}); err != nil {
+			log.Fatalf("daemon exited: %v\n", err)
+		}
+		return
+	}
+
+	if err := runOnce(ctx); err != nil {
+		log.Fatalf("%v\n", err)
+	}
+}
+
+type scanOpts struct {
+	days    int
+	dryRun  bool
+	workers int
+
+	reportFormat, reportOut, stateFile string
+}
+
+// runScan performs one full scan pass: it runs the scanner, flushes the
+// state cache, writes the report and updates the Prometheus metrics. Both
+// batch and --serve mode call this, so they share one collection path.
+func runScan(ctx context.Context, registry *dtr.Client, cache *statecache.Cache, namespaces []config.NamespaceRule, opts scanOpts) error {
+	start := time.Now()
+	today := start.UTC()
+
+	s := scanner.New(registry, scanner.Config{
+		Days:    opts.days,
+		DryRun:  opts.dryRun,
+		Today:   today,
+		Workers: opts.workers,
+		Cache:   cache,
+	})
+
+	errs := s.Run(ctx, namespaces)
+	for _, err := range errs {
+		log.Printf("scan error: %v", err)
+	}
+
+	if err := cache.Save(); err != nil {
+		log.Printf("Error saving state file %v: %v\n", opts.stateFile, err)
+	}
+
+	if err := writeReport(s.Reports(), opts.reportFormat, opts.reportOut); err != nil {
+		log.Printf("Error writing report: %v", err)
+	}
+
+	metrics.Observe(s.Reports(), len(errs), today, time.Since(start))
+
+	if len(errs) > 0 {
+		return fmt.Errorf("completed with %d error(s)", len(errs))
+	}
+	return nil
+}
+
+func writeReport(tags []report.TagReport, format, out string) error {
+	reporter, err := report.New(format)
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return fmt.Errorf("creating report file %s: %w", out, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return reporter.Report(w, report.NewCollection(tags))
+}
@@ -0,0 +1,153 @@
+// Package config loads and validates dtrscan's namespace selection file.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NamespaceRule selects and filters the repos and tags scanned within one
+// DTR namespace. A bare namespace name in the file ("acme") is equivalent
+// to {name: "acme"} with every other field left at its zero value.
+type NamespaceRule struct {
+	Name    string   `yaml:"name" json:"name"`
+	Include []string `yaml:"include,omitempty" json:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	MinDays int      `yaml:"min_days,omitempty" json:"min_days,omitempty"`
+	Os      []string `yaml:"os,omitempty" json:"os,omitempty"`
+	Arch    []string `yaml:"arch,omitempty" json:"arch,omitempty"`
+	Force   bool     `yaml:"force,omitempty" json:"force,omitempty"`
+}
+
+var allowedKeys = map[string]bool{
+	"name": true, "include": true, "exclude": true,
+	"min_days": true, "os": true, "arch": true, "force": true,
+}
+
+// Namespaces is the parsed, validated namespaces file. If there are no
+// tags for a namespace/name combination it will be ignored.
+type Namespaces struct {
+	Namespaces []NamespaceRule
+}
+
+// Load reads, parses and validates a namespaces file. Every violation
+// found is reported together, with file/line information, instead of
+// stopping at the first one.
+func Load(fileName string) (*Namespaces, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Namespaces []yaml.Node `yaml:"Namespaces"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	var ns Namespaces
+	var violations []string
+	for i, node := range raw.Namespaces {
+		rule, errs := decodeRule(node)
+		for _, e := range errs {
+			violations = append(violations, fmt.Sprintf("%s:%d: entry %d: %s", fileName, node.Line, i, e))
+		}
+		ns.Namespaces = append(ns.Namespaces, rule)
+	}
+	if len(violations) > 0 {
+		return nil, fmt.Errorf("invalid namespaces file:\n  %s", strings.Join(violations, "\n  "))
+	}
+	return &ns, nil
+}
+
+func decodeRule(node yaml.Node) (NamespaceRule, []string) {
+	var rule NamespaceRule
+	var violations []string
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if err := node.Decode(&rule.Name); err != nil {
+			return rule, []string{err.Error()}
+		}
+		if rule.Name == "" {
+			violations = append(violations, "name must not be empty")
+		}
+		return rule, violations
+
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if !allowedKeys[key] {
+				violations = append(violations, fmt.Sprintf("unknown key %q", key))
+			}
+		}
+		if err := node.Decode(&rule); err != nil {
+			return rule, append(violations, err.Error())
+		}
+		if rule.Name == "" {
+			violations = append(violations, "name is required")
+		}
+		if len(rule.Include) > 0 && len(rule.Exclude) > 0 {
+			violations = append(violations, "include and exclude are mutually exclusive")
+		}
+		for _, pattern := range rule.Include {
+			if _, err := path.Match(pattern, "x"); err != nil {
+				violations = append(violations, fmt.Sprintf("invalid include glob %q: %v", pattern, err))
+			}
+		}
+		for _, pattern := range rule.Exclude {
+			if _, err := path.Match(pattern, "x"); err != nil {
+				violations = append(violations, fmt.Sprintf("invalid exclude glob %q: %v", pattern, err))
+			}
+		}
+		if rule.MinDays < 0 {
+			violations = append(violations, "min_days must not be negative")
+		}
+		return rule, violations
+
+	default:
+		return rule, []string{"entry must be a namespace name or a mapping"}
+	}
+}
+
+// RepoAllowed reports whether a repo name passes this rule's include/exclude
+// globs. Include, when set, is an allow-list; exclude always wins.
+func (r NamespaceRule) RepoAllowed(name string) bool {
+	if len(r.Include) > 0 && !matchesAny(r.Include, name) {
+		return false
+	}
+	return !matchesAny(r.Exclude, name)
+}
+
+// OsAllowed reports whether os passes this rule's os filter.
+func (r NamespaceRule) OsAllowed(os string) bool {
+	return len(r.Os) == 0 || contains(r.Os, os)
+}
+
+// ArchAllowed reports whether arch passes this rule's arch filter.
+func (r NamespaceRule) ArchAllowed(arch string) bool {
+	return len(r.Arch) == 0 || contains(r.Arch, arch)
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func load(t *testing.T, contents string) (*Namespaces, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "namespaces.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+func TestLoadBareNameEntry(t *testing.T) {
+	ns, err := load(t, "Namespaces:\n  - acme\n")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(ns.Namespaces) != 1 || ns.Namespaces[0].Name != "acme" {
+		t.Fatalf("got %+v, want a single rule named acme", ns.Namespaces)
+	}
+}
+
+func TestLoadMappingEntry(t *testing.T) {
+	ns, err := load(t, `
+Namespaces:
+  - name: acme
+    include: ["web-*"]
+    os: ["linux"]
+    arch: ["amd64"]
+    min_days: 7
+    force: true
+`)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	rule := ns.Namespaces[0]
+	if rule.Name != "acme" || rule.MinDays != 7 || !rule.Force {
+		t.Fatalf("got %+v", rule)
+	}
+}
+
+func TestLoadRejectsUnknownKey(t *testing.T) {
+	_, err := load(t, "Namespaces:\n  - name: acme\n    typo_key: 1\n")
+	if err == nil || !strings.Contains(err.Error(), `unknown key "typo_key"`) {
+		t.Fatalf("got %v, want an unknown key violation", err)
+	}
+}
+
+func TestLoadReportsAllViolationsTogether(t *testing.T) {
+	_, err := load(t, `
+Namespaces:
+  - name: acme
+    include: ["*"]
+    exclude: ["*"]
+    min_days: -1
+  - name: ""
+`)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"mutually exclusive", "min_days must not be negative", "name is required"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing expected violation %q", err, want)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidGlob(t *testing.T) {
+	_, err := load(t, "Namespaces:\n  - name: acme\n    include: [\"[\"]\n")
+	if err == nil || !strings.Contains(err.Error(), "invalid include glob") {
+		t.Fatalf("got %v, want an invalid glob violation", err)
+	}
+}
+
+func TestRepoAllowed(t *testing.T) {
+	cases := []struct {
+		name    string
+		rule    NamespaceRule
+		repo    string
+		allowed bool
+	}{
+		{"no filters", NamespaceRule{}, "anything", true},
+		{"include match", NamespaceRule{Include: []string{"web-*"}}, "web-app", true},
+		{"include no match", NamespaceRule{Include: []string{"web-*"}}, "db-app", false},
+		{"exclude match", NamespaceRule{Exclude: []string{"*-internal"}}, "app-internal", false},
+		{"exclude no match", NamespaceRule{Exclude: []string{"*-internal"}}, "app-public", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.rule.RepoAllowed(c.repo); got != c.allowed {
+				t.Errorf("RepoAllowed(%q) = %v, want %v", c.repo, got, c.allowed)
+			}
+		})
+	}
+}
+
+func TestOsAndArchAllowed(t *testing.T) {
+	rule := NamespaceRule{Os: []string{"linux"}, Arch: []string{"amd64", "arm64"}}
+
+	if !rule.OsAllowed("linux") || rule.OsAllowed("windows") {
+		t.Fatal("OsAllowed did not filter correctly")
+	}
+	if !rule.ArchAllowed("arm64") || rule.ArchAllowed("386") {
+		t.Fatal("ArchAllowed did not filter correctly")
+	}
+
+	var unfiltered NamespaceRule
+	if !unfiltered.OsAllowed("anything") || !unfiltered.ArchAllowed("anything") {
+		t.Fatal("an empty filter should allow everything")
+	}
+}
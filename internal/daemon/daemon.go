@@ -0,0 +1,88 @@
+// Package daemon turns a single scan pass into a resident process: it
+// reruns the scan on a fixed interval and exposes Prometheus metrics,
+// liveness and readiness endpoints so dtrscan can run as a Kubernetes
+// sidecar or deployment instead of a cron one-shot.
+package daemon
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Pinger makes a cheap authenticated call against the registry, used to
+// answer /healthz.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Options configures Serve.
+type Options struct {
+	Addr     string
+	Interval time.Duration
+	Registry Pinger
+	// Scan runs one full scan iteration, updating metrics itself.
+	Scan func(ctx context.Context) error
+}
+
+// Serve runs the scan loop and the metrics/health HTTP server until ctx
+// is cancelled. It blocks until the HTTP server has shut down.
+func Serve(ctx context.Context, opts Options) error {
+	var ready int32
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		hctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		if err := opts.Registry.Ping(hctx); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&ready) == 0 {
+			http.Error(w, "first scan not yet complete", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+
+	srv := &http.Server{Addr: opts.Addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	go runLoop(ctx, opts, &ready)
+
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func runLoop(ctx context.Context, opts Options, ready *int32) {
+	for {
+		if err := opts.Scan(ctx); err != nil {
+			log.Printf("scan iteration failed: %v", err)
+		}
+		atomic.StoreInt32(ready, 1)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(opts.Interval):
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Package dtr is a typed Docker Trusted Registry client. It implements
+// scanner.Registry so internal/scanner never has to know DTR's URL
+// shapes or auth scheme.
+package dtr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"dtrscan/internal/scanner"
+)
+
+const (
+	apiCall  = "api/v0/repositories"
+	pageSize = "1000000"
+)
+
+// Config holds everything the client needs to authenticate against DTR
+// and how patient to be with it.
+type Config struct {
+	UserId string
+	Token  string
+	Url    string
+
+	Retries      int
+	RetryBackoff time.Duration
+	RetryTimeout time.Duration
+	HTTPTimeout  time.Duration
+}
+
+// Client is a scanner.Registry backed by a DTR server.
+type Client struct {
+	cfg    Config
+	client *retryClient
+}
+
+var _ scanner.Registry = (*Client)(nil)
+
+func NewClient(cfg Config) *Client {
+	return &Client{
+		cfg:    cfg,
+		client: newRetryClient(cfg.HTTPTimeout, cfg.Retries, cfg.RetryBackoff, cfg.RetryTimeout),
+	}
+}
+
+// ListRepositories calls {url}/api/v0/repositories/{ns}/?pageSize=1000000.
+// If pageSize is not set at most 10 results are returned by default.
+func (c *Client) ListRepositories(ctx context.Context, ns string) (*scanner.Repositories, error) {
+	endPoint := fmt.Sprintf("%s/%s/%s", c.cfg.Url, apiCall, ns+"/?pageSize="+pageSize)
+
+	body, err := c.get(ctx, endPoint)
+	if err != nil {
+		return nil, err
+	}
+	rp := new(scanner.Repositories)
+	if err := json.Unmarshal(body, rp); err != nil {
+		return nil, fmt.Errorf("decoding repositories for %s: %w", ns, err)
+	}
+	return rp, nil
+}
+
+// ListTags calls {url}/api/v0/repositories/{ns}/{name}/tags.
+func (c *Client) ListTags(ctx context.Context, ns, name string) ([]scanner.Tags, error) {
+	endPoint := fmt.Sprintf("%s/%s/%s/%s/tags", c.cfg.Url, apiCall, ns, name)
+
+	body, err := c.get(ctx, endPoint)
+	if err != nil {
+		return nil, err
+	}
+	var tags []scanner.Tags
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return nil, fmt.Errorf("decoding tags for %s/%s: %w", ns, name, err)
+	}
+	return tags, nil
+}
+
+// GetTagDetail calls {url}/api/v0/repositories/{ns}/{name}/tags/{reference}
+// where reference is the tag name.
+func (c *Client) GetTagDetail(ctx context.Context, ns, name, tag string) ([]scanner.TagDetail, error) {
+	endPoint := fmt.Sprintf("%s/%s/%s/%s/tags/%s", c.cfg.Url, apiCall, ns, name, tag)
+
+	body, err := c.get(ctx, endPoint)
+	if err != nil {
+		return nil, err
+	}
+	var tagDetail []scanner.TagDetail
+	if err := json.Unmarshal(body, &tagDetail); err != nil {
+		return nil, fmt.Errorf("decoding tag detail for %s/%s:%s: %w", ns, name, tag, err)
+	}
+	return tagDetail, nil
+}
+
+// Ping makes a cheap authenticated call to confirm the configured
+// credentials are accepted by DTR.
+func (c *Client) Ping(ctx context.Context) error {
+	endPoint := fmt.Sprintf("%s/api/v0/meta/settings", c.cfg.Url)
+	_, err := c.get(ctx, endPoint)
+	return err
+}
+
+// StartScan calls {url}/api/v0/imagescan/scan/{ns}/{name}/{reference}/{os}/{arch}.
+func (c *Client) StartScan(ctx context.Context, ns, name, tag, os, arch string) error {
+	endPoint := fmt.Sprintf("%s/api/v0/imagescan/scan/%s/%s/%s/%s/%s", c.cfg.Url, ns, name, tag, os, arch)
+
+	req, err := http.NewRequest("POST", endPoint, nil)
+	if err != nil {
+		return fmt.Errorf("building scan request for %s/%s:%s: %w", ns, name, tag, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.cfg.UserId, c.cfg.Token)
+
+	resp, err := c.client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, endPoint string) ([]byte, error) {
+	req, err := http.NewRequest("GET", endPoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.cfg.UserId, c.cfg.Token)
+
+	resp, err := c.client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response from %s: %w", endPoint, err)
+	}
+	return body, nil
+}
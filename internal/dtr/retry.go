@@ -0,0 +1,77 @@
+package dtr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// retryClient wraps an *http.Client and retries requests that fail with a
+// 5xx status code or a network-level error (including timeouts), backing
+// off exponentially between attempts. It gives up once either Retries
+// retries have been made after the first attempt, RetryTimeout has elapsed
+// since the first attempt, or ctx is cancelled, whichever comes first.
+type retryClient struct {
+	client       *http.Client
+	retries      int
+	backoff      time.Duration
+	retryTimeout time.Duration
+}
+
+func newRetryClient(httpTimeout time.Duration, retries int, backoff, retryTimeout time.Duration) *retryClient {
+	return &retryClient{
+		client:       &http.Client{Timeout: httpTimeout},
+		retries:      retries,
+		backoff:      backoff,
+		retryTimeout: retryTimeout,
+	}
+}
+
+// Do sends req with ctx attached, retrying on 5xx responses and transient
+// network errors.
+func (c *retryClient) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	start := time.Now()
+	backoff := c.backoff
+	var lastErr error
+
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			if c.retryTimeout > 0 && time.Since(start) >= c.retryTimeout {
+				return nil, fmt.Errorf("giving up on %s after %v: %w", req.URL, time.Since(start), lastErr)
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			if !isRetryableErr(err) {
+				return nil, err
+			}
+			continue
+		}
+
+		if resp.StatusCode < 500 {
+			return resp, nil
+		}
+		lastErr = fmt.Errorf("%s returned %s", req.URL, resp.Status)
+		resp.Body.Close()
+	}
+	return nil, fmt.Errorf("%s: exhausted %d retries: %w", req.URL, c.retries, lastErr)
+}
+
+func isRetryableErr(err error) bool {
+	_, ok := err.(net.Error)
+	return ok
+}
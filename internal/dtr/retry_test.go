@@ -0,0 +1,105 @@
+package dtr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryClientRetriesOn5xxThenSucceeds(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newRetryClient(time.Second, 5, time.Millisecond, time.Second)
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+
+	resp, err := c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	if calls != 3 {
+		t.Fatalf("got %d calls, want 3", calls)
+	}
+}
+
+func TestRetryClientGivesUpAfterRetries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := newRetryClient(time.Second, 2, time.Millisecond, time.Second)
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+
+	_, err := c.Do(context.Background(), req)
+	if err == nil {
+		t.Fatal("expected an error after exhausting retries")
+	}
+	if calls != 3 { // initial attempt + 2 retries
+		t.Fatalf("got %d calls, want 3", calls)
+	}
+}
+
+func TestRetryClientStopsOnContextCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := newRetryClient(time.Second, 10, 50*time.Millisecond, time.Minute)
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Do(ctx, req)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("got err %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Do did not return after context cancellation")
+	}
+}
+
+func TestRetryClientGivesUpAfterRetryTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := newRetryClient(time.Second, 100, 20*time.Millisecond, 50*time.Millisecond)
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+
+	start := time.Now()
+	_, err := c.Do(context.Background(), req)
+	if err == nil {
+		t.Fatal("expected an error after exceeding retry-timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("took %v, want it to give up around the 50ms retry-timeout", elapsed)
+	}
+}
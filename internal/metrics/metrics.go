@@ -0,0 +1,109 @@
+// Package metrics exposes the outcome of a scan run as Prometheus
+// metrics, fed from the same TagReport records both the batch and serve
+// modes produce.
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"dtrscan/internal/report"
+)
+
+var (
+	tagsTotal = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dtrscan_tags_total",
+		Help: "Number of tags last seen for a repository.",
+	}, []string{"namespace", "name"})
+
+	scanStatus = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dtrscan_scan_status",
+		Help: "1 for the last scan status reported by the registry for a tag, 0 for every other status value.",
+	}, []string{"namespace", "name", "tag", "status"})
+
+	vulns = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dtrscan_vulns",
+		Help: "Vulnerability count for a tag by severity.",
+	}, []string{"namespace", "name", "tag", "severity"})
+
+	scansStartedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dtrscan_scans_started_total",
+		Help: "Total number of scans dtrscan has requested.",
+	})
+
+	scanErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dtrscan_scan_errors_total",
+		Help: "Total number of errors encountered while scanning.",
+	})
+
+	lastRunTimestamp = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dtrscan_last_run_timestamp_seconds",
+		Help: "Unix time of the last completed scan run.",
+	})
+
+	runDuration = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dtrscan_run_duration_seconds",
+		Help: "Duration of the last completed scan run, in seconds.",
+	})
+)
+
+type repoKey struct{ namespace, name string }
+
+type tagKey struct{ namespace, name, tag string }
+
+// lastStatus remembers the status label last set to 1 for each tag, so
+// Observe can reset it to 0 when the status changes instead of leaving a
+// stale series stuck at 1 forever.
+var lastStatus = struct {
+	mu sync.Mutex
+	m  map[tagKey]string
+}{m: map[tagKey]string{}}
+
+// Observe updates every metric from one scan run's tag reports and error
+// count.
+func Observe(tags []report.TagReport, errCount int, runAt time.Time, duration time.Duration) {
+	tagCounts := map[repoKey]int{}
+
+	for _, t := range tags {
+		tagCounts[repoKey{t.Namespace, t.Name}]++
+
+		status := strconv.Itoa(t.LastScanStatus)
+		setScanStatus(t.Namespace, t.Name, t.Tag, status)
+		vulns.WithLabelValues(t.Namespace, t.Name, t.Tag, "critical").Set(float64(t.Vulns.Critical))
+		vulns.WithLabelValues(t.Namespace, t.Name, t.Tag, "major").Set(float64(t.Vulns.Major))
+		vulns.WithLabelValues(t.Namespace, t.Name, t.Tag, "minor").Set(float64(t.Vulns.Minor))
+
+		if t.Action == report.ActionScanStarted {
+			scansStartedTotal.Inc()
+		}
+	}
+
+	for repo, n := range tagCounts {
+		tagsTotal.WithLabelValues(repo.namespace, repo.name).Set(float64(n))
+	}
+
+	scanErrorsTotal.Add(float64(errCount))
+	lastRunTimestamp.Set(float64(runAt.Unix()))
+	runDuration.Set(duration.Seconds())
+}
+
+// setScanStatus sets status to 1 for a tag and, if its status changed
+// since the last run, resets the previous status label to 0 so dashboards
+// built on "status == 1" don't see every status a tag has ever had.
+func setScanStatus(namespace, name, tag, status string) {
+	key := tagKey{namespace, name, tag}
+
+	lastStatus.mu.Lock()
+	prev, ok := lastStatus.m[key]
+	lastStatus.m[key] = status
+	lastStatus.mu.Unlock()
+
+	if ok && prev != status {
+		scanStatus.WithLabelValues(namespace, name, tag, prev).Set(0)
+	}
+	scanStatus.WithLabelValues(namespace, name, tag, status).Set(1)
+}
@@ -0,0 +1,37 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+type csvReporter struct{}
+
+var csvHeader = []string{"namespace", "name", "tag", "digest", "os", "arch",
+	"last_scan_status", "check_completed_at", "days_since", "action",
+	"critical", "major", "minor"}
+
+func (csvReporter) Report(w io.Writer, c *Collection) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, repo := range c.Repos {
+		for _, t := range repo.Tags {
+			row := []string{
+				t.Namespace, t.Name, t.Tag, t.Digest, t.Os, t.Arch,
+				strconv.Itoa(t.LastScanStatus), t.CheckCompletedAt.Format(time.RFC3339),
+				strconv.Itoa(t.DaysSince), t.Action,
+				strconv.Itoa(t.Vulns.Critical), strconv.Itoa(t.Vulns.Major), strconv.Itoa(t.Vulns.Minor),
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	return cw.Error()
+}
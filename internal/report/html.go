@@ -0,0 +1,32 @@
+package report
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlTmpl = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head><title>dtrscan report</title></head>
+<body>
+<h1>dtrscan report</h1>
+<p>{{.Global.TotalRepos}} repos, {{.Global.TotalTags}} tags
+   ({{.Global.Critical}} critical, {{.Global.Major}} major, {{.Global.Minor}} minor)</p>
+{{range .Repos}}
+<h2>{{.Namespace}}/{{.Name}}</h2>
+<table border="1">
+<tr><th>Tag</th><th>Status</th><th>Action</th><th>Critical</th><th>Major</th><th>Minor</th><th>Checked</th></tr>
+{{range .Tags}}
+<tr><td>{{.Tag}}</td><td>{{.LastScanStatus}}</td><td>{{.Action}}</td><td>{{.Vulns.Critical}}</td><td>{{.Vulns.Major}}</td><td>{{.Vulns.Minor}}</td><td>{{.CheckCompletedAt}}</td></tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+type htmlReporter struct{}
+
+func (htmlReporter) Report(w io.Writer, c *Collection) error {
+	return htmlTmpl.Execute(w, c)
+}
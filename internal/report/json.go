@@ -0,0 +1,14 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type jsonReporter struct{}
+
+func (jsonReporter) Report(w io.Writer, c *Collection) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
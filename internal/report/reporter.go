@@ -0,0 +1,25 @@
+package report
+
+import (
+	"fmt"
+	"io"
+)
+
+// Reporter renders a Collection to w in its own format.
+type Reporter interface {
+	Report(w io.Writer, c *Collection) error
+}
+
+// New returns the Reporter for the given --report-format value.
+func New(format string) (Reporter, error) {
+	switch format {
+	case "", "json":
+		return jsonReporter{}, nil
+	case "csv":
+		return csvReporter{}, nil
+	case "html":
+		return htmlReporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown report format %q (want json, csv or html)", format)
+	}
+}
@@ -0,0 +1,61 @@
+package report
+
+// Rollup is an aggregate of the tags it was computed over, mirroring the
+// severity-bucket rollups Clair-based scanners report per repo/namespace.
+type Rollup struct {
+	TotalRepos    int `json:"total_repos"`
+	TotalTags     int `json:"total_tags"`
+	Critical      int `json:"critical"`
+	Major         int `json:"major"`
+	Minor         int `json:"minor"`
+	ScansStarted  int `json:"scans_started"`
+	ScansPending  int `json:"scans_pending"`
+	ScansUpToDate int `json:"scans_up_to_date"`
+	ScansCached   int `json:"scans_cached"`
+}
+
+// Collection is a full scan run: the per-repo tag reports plus the global
+// and per-namespace rollups computed from them.
+type Collection struct {
+	Repos       []RepoReport      `json:"repos"`
+	Global      Rollup            `json:"global"`
+	ByNamespace map[string]Rollup `json:"by_namespace"`
+}
+
+// NewCollection groups tags into repos and computes the global and
+// per-namespace rollups.
+func NewCollection(tags []TagReport) *Collection {
+	repos := Group(tags)
+	c := &Collection{Repos: repos, ByNamespace: map[string]Rollup{}}
+
+	for _, repo := range repos {
+		nsRollup := c.ByNamespace[repo.Namespace]
+		c.Global.TotalRepos++
+		nsRollup.TotalRepos++
+
+		for _, t := range repo.Tags {
+			addTag(&c.Global, t)
+			addTag(&nsRollup, t)
+		}
+		c.ByNamespace[repo.Namespace] = nsRollup
+	}
+	return c
+}
+
+func addTag(r *Rollup, t TagReport) {
+	r.TotalTags++
+	r.Critical += t.Vulns.Critical
+	r.Major += t.Vulns.Major
+	r.Minor += t.Vulns.Minor
+
+	switch t.Action {
+	case ActionScanStarted:
+		r.ScansStarted++
+	case ActionScanPending:
+		r.ScansPending++
+	case ActionUpToDate:
+		r.ScansUpToDate++
+	case ActionCachedSkip:
+		r.ScansCached++
+	}
+}
@@ -0,0 +1,65 @@
+// Package report collects the outcome of inspecting each tag into a
+// structured form that can be rendered as JSON, CSV or HTML instead of
+// the free-form log lines dtrscan used to print.
+package report
+
+import "time"
+
+// Action values a TagReport's Action field can hold.
+const (
+	ActionScanStarted = "scan_started"
+	ActionScanFailed  = "scan_failed"
+	ActionWouldScan   = "would_scan_dry_run"
+	ActionScanPending = "scan_pending"
+	ActionUpToDate    = "up_to_date"
+	ActionCachedSkip  = "cached_skip"
+	ActionUnknown     = "unknown"
+)
+
+// VulnSummary is the severity breakdown DTR reported for a tag.
+type VulnSummary struct {
+	Critical int `json:"critical"`
+	Major    int `json:"major"`
+	Minor    int `json:"minor"`
+}
+
+// TagReport is the outcome of inspecting a single tag.
+type TagReport struct {
+	Namespace        string      `json:"namespace"`
+	Name             string      `json:"name"`
+	Tag              string      `json:"tag"`
+	Digest           string      `json:"digest"`
+	Os               string      `json:"os"`
+	Arch             string      `json:"arch"`
+	LastScanStatus   int         `json:"last_scan_status"`
+	CheckCompletedAt time.Time   `json:"check_completed_at"`
+	DaysSince        int         `json:"days_since"`
+	Action           string      `json:"action"`
+	Vulns            VulnSummary `json:"vulns"`
+}
+
+// RepoReport groups every inspected tag belonging to one namespace/name
+// repository.
+type RepoReport struct {
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Tags      []TagReport `json:"tags"`
+}
+
+// Group buckets a flat list of tag reports into one RepoReport per
+// namespace/name pair, preserving first-seen order.
+func Group(tags []TagReport) []RepoReport {
+	index := map[string]int{}
+	var repos []RepoReport
+	for _, t := range tags {
+		key := t.Namespace + "/" + t.Name
+		i, ok := index[key]
+		if !ok {
+			i = len(repos)
+			index[key] = i
+			repos = append(repos, RepoReport{Namespace: t.Namespace, Name: t.Name})
+		}
+		repos[i].Tags = append(repos[i].Tags, t)
+	}
+	return repos
+}
@@ -0,0 +1,107 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"dtrscan/internal/config"
+)
+
+// EffectiveTag is one tag a repo selected after applying a NamespaceRule's
+// os/arch filters.
+type EffectiveTag struct {
+	Tag  string `json:"tag"`
+	Os   string `json:"os"`
+	Arch string `json:"arch"`
+}
+
+// EffectiveRepo is one repository a NamespaceRule's include/exclude filters
+// selected, with the tags that passed its os/arch filters.
+type EffectiveRepo struct {
+	Name string         `json:"name"`
+	Tags []EffectiveTag `json:"tags"`
+}
+
+// EffectiveRule is what one namespace rule resolves to against the
+// registry, for --dump-effective-config to show instead of just echoing
+// the parsed namespaces file back.
+type EffectiveRule struct {
+	Rule  config.NamespaceRule `json:"rule"`
+	Repos []EffectiveRepo      `json:"repos"`
+}
+
+// Explain resolves each rule against registry the same way Run's
+// scanNamespace/scanRepo/scanTag would filter it, without scanning or
+// touching a cache, and returns the repos and tags the rule actually
+// selects.
+func Explain(ctx context.Context, registry Registry, rules []config.NamespaceRule) ([]EffectiveRule, []error) {
+	results := make([]EffectiveRule, len(rules))
+	for i, rule := range rules {
+		results[i] = EffectiveRule{Rule: rule}
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	addErr := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	p := newPool(len(rules))
+	for i, rule := range rules {
+		i, rule := i, rule
+		p.submit(func() error {
+			return explainRule(ctx, registry, rule, &results[i], &mu, addErr)
+		})
+	}
+	errs = append(errs, p.wait()...)
+	return results, errs
+}
+
+func explainRule(ctx context.Context, registry Registry, rule config.NamespaceRule, result *EffectiveRule, mu *sync.Mutex, addErr func(error)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ns := rule.Name
+	repos, err := registry.ListRepositories(ctx, ns)
+	if err != nil {
+		return fmt.Errorf("namespace %s: %w", ns, err)
+	}
+	for _, name := range repos.Repository {
+		if !rule.RepoAllowed(name.Name) {
+			continue
+		}
+		er := explainRepo(ctx, registry, rule, name.Name, addErr)
+		mu.Lock()
+		result.Repos = append(result.Repos, er)
+		mu.Unlock()
+	}
+	return nil
+}
+
+func explainRepo(ctx context.Context, registry Registry, rule config.NamespaceRule, name string, addErr func(error)) EffectiveRepo {
+	ns := rule.Name
+	er := EffectiveRepo{Name: name}
+
+	tags, err := registry.ListTags(ctx, ns, name)
+	if err != nil {
+		addErr(fmt.Errorf("repo %s/%s: %w", ns, name, err))
+		return er
+	}
+	for _, tag := range tags {
+		details, err := registry.GetTagDetail(ctx, ns, name, tag.Name)
+		if err != nil {
+			addErr(fmt.Errorf("tag %s/%s:%s: %w", ns, name, tag.Name, err))
+			continue
+		}
+		for _, d := range details {
+			if !rule.OsAllowed(d.Manifest.Os) || !rule.ArchAllowed(d.Manifest.Architecture) {
+				continue
+			}
+			er.Tags = append(er.Tags, EffectiveTag{Tag: d.Name, Os: d.Manifest.Os, Arch: d.Manifest.Architecture})
+		}
+	}
+	return er
+}
@@ -0,0 +1,79 @@
+package scanner
+
+import "sync"
+
+// pool runs jobs on a fixed set of long-lived workers pulling from an
+// unbounded queue. Submitting a job never blocks on a worker being free,
+// so it is safe to cascade further submissions from within a running job
+// (e.g. a namespace job submitting one job per repository) without
+// deadlocking once every worker is busy doing exactly that.
+type pool struct {
+	workers int
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []func() error
+	closed bool
+
+	wg sync.WaitGroup
+
+	errsMu sync.Mutex
+	errs   []error
+}
+
+func newPool(workers int) *pool {
+	if workers <= 0 {
+		workers = 1
+	}
+	p := &pool{workers: workers}
+	p.cond = sync.NewCond(&p.mu)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// submit queues fn to run on the pool. It never blocks waiting for a free
+// worker, so it's always safe to call from a job already running on p.
+func (p *pool) submit(fn func() error) {
+	p.wg.Add(1)
+	p.mu.Lock()
+	p.queue = append(p.queue, fn)
+	p.mu.Unlock()
+	p.cond.Signal()
+}
+
+func (p *pool) work() {
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if len(p.queue) == 0 && p.closed {
+			p.mu.Unlock()
+			return
+		}
+		fn := p.queue[0]
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+
+		if err := fn(); err != nil {
+			p.errsMu.Lock()
+			p.errs = append(p.errs, err)
+			p.errsMu.Unlock()
+		}
+		p.wg.Done()
+	}
+}
+
+// wait blocks until every submitted job (including jobs submitted by other
+// jobs) has finished, shuts down the pool's workers and returns the
+// aggregated errors. The pool must not be reused after wait returns.
+func (p *pool) wait() []error {
+	p.wg.Wait()
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.cond.Broadcast()
+	return p.errs
+}
@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPoolCascadingSubmitDoesNotDeadlock exercises exactly the pattern
+// Run uses: a job submits further jobs from inside the pool, several
+// levels deep, with more concurrently-live jobs than there are workers.
+func TestPoolCascadingSubmitDoesNotDeadlock(t *testing.T) {
+	const workers = 2
+
+	done := make(chan struct{})
+	var ran int32
+
+	go func() {
+		p := newPool(workers)
+
+		var cascade func(depth int)
+		cascade = func(depth int) {
+			atomic.AddInt32(&ran, 1)
+			if depth <= 0 {
+				return
+			}
+			for i := 0; i < 3; i++ {
+				p.submit(func() error {
+					cascade(depth - 1)
+					return nil
+				})
+			}
+		}
+		p.submit(func() error { cascade(4); return nil })
+
+		if errs := p.wait(); len(errs) != 0 {
+			t.Errorf("unexpected errors: %v", errs)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pool deadlocked: cascading submissions never completed")
+	}
+
+	if atomic.LoadInt32(&ran) == 0 {
+		t.Fatal("no jobs ran")
+	}
+}
+
+func TestPoolAggregatesErrors(t *testing.T) {
+	p := newPool(3)
+	want := errors.New("boom")
+
+	p.submit(func() error { return nil })
+	p.submit(func() error { return want })
+	p.submit(func() error { return nil })
+
+	errs := p.wait()
+	if len(errs) != 1 || errs[0] != want {
+		t.Fatalf("got errs %v, want [%v]", errs, want)
+	}
+}
+
+func TestPoolZeroOrNegativeWorkersDefaultsToOne(t *testing.T) {
+	p := newPool(0)
+	if p.workers != 1 {
+		t.Fatalf("got %d workers, want 1", p.workers)
+	}
+	if p := newPool(-3); p.workers != 1 {
+		t.Fatalf("got %d workers for a negative count, want 1", p.workers)
+	}
+}
@@ -0,0 +1,14 @@
+package scanner
+
+import "context"
+
+// Registry is the container-registry backend a Scanner walks. Today the
+// only implementation is internal/dtr's DTR client; a Harbor or plain
+// registry/v2 client can implement this same interface without the
+// scanner needing to know about it.
+type Registry interface {
+	ListRepositories(ctx context.Context, namespace string) (*Repositories, error)
+	ListTags(ctx context.Context, namespace, name string) ([]Tags, error)
+	GetTagDetail(ctx context.Context, namespace, name, tag string) ([]TagDetail, error)
+	StartScan(ctx context.Context, namespace, name, tag, os, arch string) error
+}
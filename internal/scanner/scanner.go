@@ -0,0 +1,275 @@
+// Package scanner drives a registry scan: it walks repositories, tags and
+// tag details, decides whether a tag needs rescanning, and fires off the
+// scan request. Work is fanned out across a bounded pool of workers so a
+// registry with thousands of tags can be scanned without doing every
+// round trip serially.
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"dtrscan/internal/config"
+	"dtrscan/internal/report"
+	"dtrscan/internal/statecache"
+)
+
+// Config is the scan policy: when a tag is considered stale and how much
+// concurrency to use. Anything backend-specific (credentials, URLs,
+// timeouts) lives in the Registry implementation instead.
+type Config struct {
+	Days    int
+	DryRun  bool
+	Today   time.Time
+	Workers int
+
+	// Cache, when set, lets the scanner skip tags whose last check is
+	// still fresh instead of re-deciding on every run.
+	Cache *statecache.Cache
+}
+
+// Scanner walks a set of namespaces on a Registry and scans their tags.
+type Scanner struct {
+	registry Registry
+	cfg      Config
+
+	mu      sync.Mutex
+	reports []report.TagReport
+}
+
+func New(registry Registry, cfg Config) *Scanner {
+	return &Scanner{registry: registry, cfg: cfg}
+}
+
+// Reports returns the TagReport collected for every tag inspected by the
+// last call to Run.
+func (s *Scanner) Reports() []report.TagReport {
+	return s.reports
+}
+
+func (s *Scanner) addReport(r report.TagReport) {
+	s.mu.Lock()
+	s.reports = append(s.reports, r)
+	s.mu.Unlock()
+}
+
+// Run scans every namespace concurrently and returns the errors collected
+// along the way. A failure in one namespace, repo or tag does not stop the
+// others from being scanned. Cancelling ctx aborts in-flight registry
+// calls and stops new ones from being started.
+func (s *Scanner) Run(ctx context.Context, rules []config.NamespaceRule) []error {
+	s.mu.Lock()
+	s.reports = nil
+	s.mu.Unlock()
+
+	p := newPool(s.cfg.Workers)
+	for _, rule := range rules {
+		rule := rule
+		p.submit(func() error {
+			return s.scanNamespace(ctx, rule, p)
+		})
+	}
+	return p.wait()
+}
+
+func (s *Scanner) scanNamespace(ctx context.Context, rule config.NamespaceRule, p *pool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ns := rule.Name
+	repos, err := s.registry.ListRepositories(ctx, ns)
+	if err != nil {
+		return fmt.Errorf("namespace %s: %w", ns, err)
+	}
+	for _, name := range repos.Repository {
+		name := name
+		if !rule.RepoAllowed(name.Name) {
+			continue
+		}
+		p.submit(func() error {
+			return s.scanRepo(ctx, rule, name.Name, p)
+		})
+	}
+	return nil
+}
+
+func (s *Scanner) scanRepo(ctx context.Context, rule config.NamespaceRule, name string, p *pool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ns := rule.Name
+	tags, err := s.registry.ListTags(ctx, ns, name)
+	if err != nil {
+		return fmt.Errorf("repo %s/%s: %w", ns, name, err)
+	}
+	for _, tag := range tags {
+		tag := tag
+		p.submit(func() error {
+			return s.scanTag(ctx, rule, name, tag.Name, p)
+		})
+	}
+	return nil
+}
+
+func (s *Scanner) scanTag(ctx context.Context, rule config.NamespaceRule, name, tag string, p *pool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ns := rule.Name
+	details, err := s.registry.GetTagDetail(ctx, ns, name, tag)
+	if err != nil {
+		return fmt.Errorf("tag %s/%s:%s: %w", ns, name, tag, err)
+	}
+	for _, d := range details {
+		d := d
+		if !rule.OsAllowed(d.Manifest.Os) || !rule.ArchAllowed(d.Manifest.Architecture) {
+			continue
+		}
+		if s.skipCached(rule, name, d) {
+			s.addCachedSkipReport(rule, name, d)
+			continue
+		}
+		p.submit(func() error {
+			return s.inspectAndScanTag(ctx, rule, name, d)
+		})
+	}
+	return nil
+}
+
+// effectiveDays returns the freshness threshold to apply for rule: its own
+// min_days if set, otherwise the scanner-wide --days value.
+func (s *Scanner) effectiveDays(rule config.NamespaceRule) int {
+	if rule.MinDays > 0 {
+		return rule.MinDays
+	}
+	return s.cfg.Days
+}
+
+// skipCached reports whether d can be skipped because the last cached
+// decision was made against the same CheckCompletedAt and is still within
+// the freshness window, and the rule isn't forcing a rescan regardless.
+func (s *Scanner) skipCached(rule config.NamespaceRule, name string, d TagDetail) bool {
+	if s.cfg.Cache == nil || rule.Force {
+		return false
+	}
+	ns := rule.Name
+	rec, ok := s.cfg.Cache.Get(ns, name, d.Name, d.Digest)
+	if !ok || !rec.CheckCompletedAt.Equal(d.VulnSummary.CheckCompletedAt) {
+		return false
+	}
+	daysSince, _, _, _ := getDifference(rec.CheckCompletedAt, s.cfg.Today)
+	if daysSince >= s.effectiveDays(rule) {
+		return false
+	}
+	log.Printf("%-27v %-16v %-45v %-45v", "Skipping (cached, unchanged)", ns, name, d.Name)
+	return true
+}
+
+// addCachedSkipReport records a TagReport for a tag skipCached decided to
+// skip, so report rollups and metrics still count it instead of it
+// silently disappearing once the cache is warm.
+func (s *Scanner) addCachedSkipReport(rule config.NamespaceRule, name string, d TagDetail) {
+	ns := rule.Name
+	daysSince, _, _, _ := getDifference(d.VulnSummary.CheckCompletedAt, s.cfg.Today)
+	s.addReport(report.TagReport{
+		Namespace:        ns,
+		Name:             name,
+		Tag:              d.Name,
+		Digest:           d.Digest,
+		Os:               d.Manifest.Os,
+		Arch:             d.Manifest.Architecture,
+		LastScanStatus:   d.VulnSummary.LastScanStatus,
+		CheckCompletedAt: d.VulnSummary.CheckCompletedAt,
+		DaysSince:        daysSince,
+		Action:           report.ActionCachedSkip,
+		Vulns: report.VulnSummary{
+			Critical: d.VulnSummary.Critical,
+			Major:    d.VulnSummary.Major,
+			Minor:    d.VulnSummary.Minor,
+		},
+	})
+}
+
+func (s *Scanner) inspectAndScanTag(ctx context.Context, rule config.NamespaceRule, name string, t TagDetail) error {
+	ns := rule.Name
+	daysSince, _, _, _ := getDifference(t.VulnSummary.CheckCompletedAt, s.cfg.Today)
+
+	if s.cfg.Cache != nil {
+		s.cfg.Cache.Put(ns, name, t.Name, t.Digest, statecache.Record{
+			LastScanStatus:   t.VulnSummary.LastScanStatus,
+			CheckCompletedAt: t.VulnSummary.CheckCompletedAt,
+			ShouldRescan:     t.VulnSummary.ShouldRescan,
+			DecidedAt:        s.cfg.Today,
+		})
+	}
+
+	tr := report.TagReport{
+		Namespace:        ns,
+		Name:             name,
+		Tag:              t.Name,
+		Digest:           t.Digest,
+		Os:               t.Manifest.Os,
+		Arch:             t.Manifest.Architecture,
+		LastScanStatus:   t.VulnSummary.LastScanStatus,
+		CheckCompletedAt: t.VulnSummary.CheckCompletedAt,
+		DaysSince:        daysSince,
+		Vulns: report.VulnSummary{
+			Critical: t.VulnSummary.Critical,
+			Major:    t.VulnSummary.Major,
+			Minor:    t.VulnSummary.Minor,
+		},
+	}
+
+	switch {
+	case t.VulnSummary.LastScanStatus == ScanPending:
+		log.Printf("%-27v %-16v %-45v %-45v %-1v %-5v %-40v",
+			"Scan is pending for", ns, name, t.Name,
+			t.VulnSummary.LastScanStatus, t.VulnSummary.ShouldRescan, t.VulnSummary.CheckCompletedAt)
+		tr.Action = report.ActionScanPending
+		s.addReport(tr)
+		return nil
+
+	case daysSince > s.effectiveDays(rule),
+		rule.Force,
+		t.VulnSummary.ShouldRescan,
+		t.VulnSummary.LastScanStatus == ScanUnknown && !t.VulnSummary.ShouldRescan:
+		if s.cfg.DryRun {
+			log.Printf("%-27v %-16v %-45v %-45v %-1v %-5v %-40v %v days ago",
+				"Will scan if no_dry_run", ns, name, t.Name,
+				t.VulnSummary.LastScanStatus, t.VulnSummary.ShouldRescan, t.VulnSummary.CheckCompletedAt, daysSince)
+			tr.Action = report.ActionWouldScan
+			s.addReport(tr)
+			return nil
+		}
+		log.Printf("%-27v %-16v %-45v %-45v %-1v %-5v %-40v %v days ago",
+			"Sending request to scan", ns, name, t.Name,
+			t.VulnSummary.LastScanStatus, t.VulnSummary.ShouldRescan, t.VulnSummary.CheckCompletedAt, daysSince)
+		err := s.registry.StartScan(ctx, ns, name, t.Name, t.Manifest.Os, t.Manifest.Architecture)
+		if err != nil {
+			tr.Action = report.ActionScanFailed
+			err = fmt.Errorf("unable to scan %s/%s:%s: %w", ns, name, t.Name, err)
+		} else {
+			tr.Action = report.ActionScanStarted
+		}
+		s.addReport(tr)
+		return err
+
+	case !t.VulnSummary.ShouldRescan && t.VulnSummary.LastScanStatus == ScanOk:
+		log.Printf("%-27v %-16v %-45v %-45v %-1v %-5v %-40v",
+			"Scan is up-to-date for", ns, name, t.Name,
+			t.VulnSummary.LastScanStatus, t.VulnSummary.ShouldRescan, t.VulnSummary.CheckCompletedAt)
+		tr.Action = report.ActionUpToDate
+		s.addReport(tr)
+		return nil
+
+	default:
+		log.Printf("%-27v %-16v %-45v %-45v",
+			"Scan status is unknown for", ns, name, t.Name)
+		tr.Action = report.ActionUnknown
+		s.addReport(tr)
+		return nil
+	}
+}
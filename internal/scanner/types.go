@@ -0,0 +1,49 @@
+package scanner
+
+import "time"
+
+// These types are the scanner's own domain model for a repository's tags.
+// A Registry implementation (DTR, Harbor, plain registry/v2, ...) is
+// responsible for translating its own API shapes into these; none of a
+// backend's URL or payload conventions should leak past Registry.
+
+type Names struct {
+	Name string `json:"name"`
+}
+
+type Repositories struct {
+	Repository []Names `json:"repositories"`
+}
+
+type Tags struct {
+	Name string
+}
+
+type Manifest struct {
+	Os           string `json:"os"`
+	Architecture string `json:"architecture"`
+}
+
+type VulnSummary struct {
+	Critical         int       `json:"critical"`
+	Major            int       `json:"major"`
+	Minor            int       `json:"minor"`
+	LastScanStatus   int       `json:"last_scan_status"`
+	CheckCompletedAt time.Time `json:"check_completed_at"`
+	ShouldRescan     bool      `json:"should_rescan"`
+}
+
+type TagDetail struct {
+	Name        string      `json:"name"`
+	Digest      string      `json:"digest"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	Manifest    Manifest    `json:"manifest"`
+	VulnSummary VulnSummary `json:"vuln_summary"`
+}
+
+const (
+	ScanUnknown = 0
+	ScanPending = 5
+	ScanOk      = 6
+)
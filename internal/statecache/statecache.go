@@ -0,0 +1,132 @@
+// Package statecache persists the last scan decision made for each tag so
+// that re-runs can skip tags that have not changed since, instead of
+// re-fetching and re-deciding on every invocation.
+package statecache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Record is the last decision made for one {namespace, name, tag, digest}.
+type Record struct {
+	LastScanStatus   int       `json:"last_scan_status"`
+	CheckCompletedAt time.Time `json:"check_completed_at"`
+	ShouldRescan     bool      `json:"should_rescan"`
+	DecidedAt        time.Time `json:"decided_at"`
+}
+
+// Cache is a flat, on-disk map of scan decisions, safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]Record
+}
+
+// DefaultPath returns dtrscan-state.json under $XDG_CACHE_HOME (or the
+// platform default user cache dir if that is unset).
+func DefaultPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "dtrscan", "dtrscan-state.json")
+}
+
+// New returns an empty cache that will be written to path on Save.
+func New(path string) *Cache {
+	return &Cache{path: path, entries: map[string]Record{}}
+}
+
+// Load reads the cache from path. A missing file is not an error; it just
+// yields an empty cache that Save will create on first flush.
+func Load(path string) (*Cache, error) {
+	c := New(path)
+
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("parsing state file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func key(ns, name, tag, digest string) string {
+	return ns + "/" + name + ":" + tag + "@" + digest
+}
+
+// Get returns the cached record for a tag, if any.
+func (c *Cache) Get(ns, name, tag, digest string) (Record, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.entries[key(ns, name, tag, digest)]
+	return r, ok
+}
+
+// Put stores the latest decision for a tag.
+func (c *Cache) Put(ns, name, tag, digest string, r Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key(ns, name, tag, digest)] = r
+}
+
+// Prune drops entries whose DecidedAt is older than maxAge relative to now.
+// A zero maxAge disables expiry.
+func (c *Cache) Prune(maxAge time.Duration, now time.Time) {
+	if maxAge <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, r := range c.entries {
+		if now.Sub(r.DecidedAt) > maxAge {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// Save writes the cache to disk atomically: it writes to a temp file in
+// the same directory and renames it into place, so a kill mid-write never
+// leaves a truncated state file behind.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating state dir %s: %w", dir, err)
+	}
+
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, ".dtrscan-state-*.json.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, c.path)
+}